@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// dsnPasswordRE matches the "user:password@" userinfo segment of a MySQL
+// DSN so its password component can be redacted from error messages.
+var dsnPasswordRE = regexp.MustCompile(`^[^:@/]*:([^@]*)@`)
+
+// fileConfig is the on-disk shape read by LoadConfigFile. It mirrors the
+// subset of mysql.Config that operators need to hand-configure, plus the
+// handful of TLS knobs dbConfFromEnv exposes via environment variables.
+type fileConfig struct {
+	DataSourceName string `json:"dataSourceName"`
+	TLSDisable     bool   `json:"tlsDisable"`
+	TLSServerName  string `json:"tlsServerName"`
+	RootCertPath   string `json:"rootCertPath"`
+	ClientCertPath string `json:"clientCertPath"`
+	ClientKeyPath  string `json:"clientKeyPath"`
+}
+
+// tlsMode returns the tlsOptions.Mode implied by fc. File configs predate
+// DB_TLS_MODE and only know disabled-or-not, so a non-disabled file config
+// always asks for full verification.
+func (fc fileConfig) tlsMode() string {
+	if fc.TLSDisable {
+		return tlsModeDisable
+	}
+	return tlsModeVerifyFull
+}
+
+// LoadConfigFile reads a JSON config file and builds a *mysql.Config from
+// it, as an alternative to dbConfFromEnv for operators who'd rather hand a
+// config file to the process than set a pile of env vars. Cert paths in the
+// file that are relative are resolved against the directory containing path,
+// not the process's working directory, so config files remain portable. It
+// also returns the tlsOptions it derived, for callers that want live cert
+// rotation (see WatchTLSConfig).
+func LoadConfigFile(path string) (*mysql.Config, tlsOptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, tlsOptions{}, fmt.Errorf("LoadConfigFile: %v", err)
+	}
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, tlsOptions{}, fmt.Errorf("LoadConfigFile: %v", err)
+	}
+
+	dconf, err := mysql.ParseDSN(fc.DataSourceName)
+	if err != nil {
+		return nil, tlsOptions{}, fmt.Errorf("LoadConfigFile: %v", scrubPassword(fc.DataSourceName, err))
+	}
+
+	if fc.TLSDisable {
+		return dconf, tlsOptions{Mode: tlsModeDisable}, nil
+	}
+	if fc.RootCertPath == "" {
+		return nil, tlsOptions{}, errCertPath
+	}
+	if fc.ClientCertPath == "" {
+		return nil, tlsOptions{}, errClientCertPath
+	}
+	if fc.ClientKeyPath == "" {
+		return nil, tlsOptions{}, errClientKeyPath
+	}
+
+	dir := filepath.Dir(path)
+	opts := tlsOptions{
+		Mode:           fc.tlsMode(),
+		CACertPath:     resolveRelative(dir, fc.RootCertPath),
+		ClientCertPath: resolveRelative(dir, fc.ClientCertPath),
+		KeyProvider:    FileKeyProvider{KeyPath: resolveRelative(dir, fc.ClientKeyPath)},
+		ServerName:     fc.TLSServerName,
+	}
+	tconfKey, err := tlsConfig(opts)
+	if err != nil {
+		return nil, tlsOptions{}, err
+	}
+	dconf.TLSConfig = tconfKey
+	return dconf, opts, nil
+}
+
+// resolveRelative returns p unchanged when it is absolute or empty, and
+// otherwise resolves it against dir (the directory holding the config file).
+func resolveRelative(dir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// scrubPassword removes any occurrence of dsn's password from err's message
+// so that credentials never make it into logs.
+func scrubPassword(dsn string, err error) error {
+	if err == nil {
+		return nil
+	}
+	m := dsnPasswordRE.FindStringSubmatch(dsn)
+	if m == nil || m[1] == "" {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), m[1], "REDACTED"))
+}
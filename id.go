@@ -0,0 +1,17 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// newID returns a random 128-bit hex id, used by backends that don't
+// generate ids themselves (MySQL relies on its uuid() insert trigger; sqlite
+// and the in-memory store use this instead).
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("newID: %v", err)
+	}
+	return fmt.Sprintf("%x", b), nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+var errNoPEMBlock = errors.New("keyprovider: no PEM block found in client certificate")
+
+// KeyProvider loads the private key matching a client certificate so
+// tlsConfig doesn't need to know whether that key lives in a plain file, an
+// HSM, or a hash-addressed keystore. certPath is always a path to the client
+// certificate; it's up to the provider to find the key for it.
+type KeyProvider interface {
+	LoadKeyPair(certPath string) (tls.Certificate, error)
+
+	// WatchDirs returns the directories a cert-rotation watcher (see
+	// WatchTLSConfig) should watch for this provider's key material to
+	// change on disk.
+	WatchDirs() []string
+}
+
+// FileKeyProvider loads the client key from a plain file alongside the
+// certificate, preserving the pre-KeyProvider behaviour driven by
+// DB_CLIENT_KEY_PATH.
+type FileKeyProvider struct {
+	KeyPath string
+}
+
+func (p FileKeyProvider) LoadKeyPair(certPath string) (tls.Certificate, error) {
+	return tls.LoadX509KeyPair(certPath, p.KeyPath)
+}
+
+func (p FileKeyProvider) WatchDirs() []string {
+	if p.KeyPath == "" {
+		return nil
+	}
+	return []string{filepath.Dir(p.KeyPath)}
+}
+
+// KeystoreKeyProvider loads the client key from a directory where each key
+// file is named by the lowercase hex SHA-256 hash of its certificate's
+// SubjectPublicKeyInfo, the convention Hyperledger Fabric-CA uses for
+// BCCSP/HSM-backed keystores. This lets an HSM or a hardware-backed
+// keystore generate the key while mycrud never has to know the filename in
+// advance.
+type KeystoreKeyProvider struct {
+	Dir string
+}
+
+func (p KeystoreKeyProvider) LoadKeyPair(certPath string) (tls.Certificate, error) {
+	certPEM, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return tls.Certificate{}, errNoPEMBlock
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	keyPath := filepath.Join(p.Dir, hex.EncodeToString(hash[:]))
+	keyPEM, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("KeystoreKeyProvider: %v", err)
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+func (p KeystoreKeyProvider) WatchDirs() []string {
+	if p.Dir == "" {
+		return nil
+	}
+	return []string{p.Dir}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeystoreKeyProviderLoadKeyPair(t *testing.T) {
+	dir := t.TempDir()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mycrud-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+
+	certPath := filepath.Join(dir, "client.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certPath, certPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile cert: %v", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	keyPath := filepath.Join(dir, hex.EncodeToString(hash[:]))
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile key: %v", err)
+	}
+
+	p := KeystoreKeyProvider{Dir: dir}
+	tlsCert, err := p.LoadKeyPair(certPath)
+	if err != nil {
+		t.Fatalf("LoadKeyPair: %v", err)
+	}
+	if len(tlsCert.Certificate) == 0 {
+		t.Fatal("LoadKeyPair: tls.Certificate has no certificate bytes")
+	}
+}
+
+func TestKeystoreKeyProviderLoadKeyPairMissingKey(t *testing.T) {
+	dir := t.TempDir()
+	certPath := writeTestCA(t, dir)
+
+	p := KeystoreKeyProvider{Dir: dir}
+	if _, err := p.LoadKeyPair(certPath); err == nil {
+		t.Fatal("LoadKeyPair with no matching key file: got nil error, want one")
+	}
+}
+
+func TestKeystoreKeyProviderWatchDirs(t *testing.T) {
+	p := KeystoreKeyProvider{Dir: "/etc/mycrud/keystore"}
+	dirs := p.WatchDirs()
+	if len(dirs) != 1 || dirs[0] != "/etc/mycrud/keystore" {
+		t.Fatalf("WatchDirs() = %v, want [/etc/mycrud/keystore]", dirs)
+	}
+	if got := (KeystoreKeyProvider{}).WatchDirs(); got != nil {
+		t.Fatalf("WatchDirs() with empty Dir = %v, want nil", got)
+	}
+}
+
+func TestFileKeyProviderWatchDirs(t *testing.T) {
+	p := FileKeyProvider{KeyPath: "/etc/mycrud/client.key"}
+	dirs := p.WatchDirs()
+	if len(dirs) != 1 || dirs[0] != "/etc/mycrud" {
+		t.Fatalf("WatchDirs() = %v, want [/etc/mycrud]", dirs)
+	}
+	if got := (FileKeyProvider{}).WatchDirs(); got != nil {
+		t.Fatalf("WatchDirs() with empty KeyPath = %v, want nil", got)
+	}
+}
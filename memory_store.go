@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+// memoryStore is an in-memory UserStore, useful for tests and for running
+// the CLI without a database. It is not durable: contents are lost when the
+// process exits.
+type memoryStore struct {
+	mu   sync.Mutex
+	byID map[string]*user
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{byID: make(map[string]*user)}
+}
+
+func (s *memoryStore) Users() ([]*user, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uu := make([]*user, 0, len(s.byID))
+	for _, u := range s.byID {
+		cp := *u
+		uu = append(uu, &cp)
+	}
+	return uu, nil
+}
+
+func (s *memoryStore) UserByID(id string) (*user, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.byID[id]
+	if !ok {
+		return nil, errUserNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (s *memoryStore) AddUser(name string) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.byID {
+		if u.name == name {
+			return errors.New("memoryStore AddUser: duplicate name")
+		}
+	}
+	now := time.Now()
+	s.byID[id] = &user{id: id, createdAt: now, updatedAt: now, name: name}
+	return nil
+}
+
+// DelUser returns nil when name doesn't match any user, the same as the
+// mysql and sqlite backends' `delete ... where name=?` affecting zero rows.
+func (s *memoryStore) DelUser(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, u := range s.byID {
+		if u.name == name {
+			delete(s.byID, id)
+			return nil
+		}
+	}
+	return nil
+}
+
+// UpdateUser returns nil when oldname doesn't match any user, the same as
+// the mysql and sqlite backends' `update ... where name=?` affecting zero
+// rows.
+func (s *memoryStore) UpdateUser(oldname, newname string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.byID {
+		if u.name == newname && u.name != oldname {
+			return errors.New("memoryStore UpdateUser: duplicate name")
+		}
+	}
+	for _, u := range s.byID {
+		if u.name == oldname {
+			u.name = newname
+			u.updatedAt = time.Now()
+			return nil
+		}
+	}
+	return nil
+}
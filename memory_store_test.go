@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestMemoryStoreCRUD(t *testing.T) {
+	s := newMemoryStore()
+
+	if err := s.AddUser("jerry"); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+	uu, err := s.Users()
+	if err != nil {
+		t.Fatalf("Users: %v", err)
+	}
+	if len(uu) != 1 || uu[0].name != "jerry" {
+		t.Fatalf("Users after add = %+v, want one user named jerry", uu)
+	}
+
+	if _, err := s.UserByID(uu[0].id); err != nil {
+		t.Fatalf("UserByID: %v", err)
+	}
+
+	if err := s.UpdateUser("jerry", "Jerry"); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	uu, _ = s.Users()
+	if len(uu) != 1 || uu[0].name != "Jerry" {
+		t.Fatalf("Users after update = %+v, want one user named Jerry", uu)
+	}
+
+	if err := s.DelUser("Jerry"); err != nil {
+		t.Fatalf("DelUser: %v", err)
+	}
+	uu, _ = s.Users()
+	if len(uu) != 0 {
+		t.Fatalf("Users after delete = %+v, want none", uu)
+	}
+}
+
+func TestMemoryStoreAddUserDuplicateName(t *testing.T) {
+	s := newMemoryStore()
+	if err := s.AddUser("jerry"); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+	if err := s.AddUser("jerry"); err == nil {
+		t.Fatal("AddUser with duplicate name: got nil error, want one")
+	}
+}
+
+func TestMemoryStoreUpdateUserDuplicateName(t *testing.T) {
+	s := newMemoryStore()
+	if err := s.AddUser("jerry"); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+	if err := s.AddUser("george"); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+	if err := s.UpdateUser("jerry", "george"); err == nil {
+		t.Fatal("UpdateUser renaming onto an existing name: got nil error, want one")
+	}
+}
+
+func TestMemoryStoreDelUserMissing(t *testing.T) {
+	s := newMemoryStore()
+	if err := s.DelUser("nobody"); err != nil {
+		t.Fatalf("DelUser of a name that doesn't exist: got %v, want nil", err)
+	}
+}
+
+func TestMemoryStoreUpdateUserMissingOldname(t *testing.T) {
+	s := newMemoryStore()
+	if err := s.UpdateUser("nobody", "somebody"); err != nil {
+		t.Fatalf("UpdateUser with a nonexistent oldname: got %v, want nil", err)
+	}
+}
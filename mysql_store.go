@@ -0,0 +1,211 @@
+package main
+
+import (
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	errCertPath       = errors.New("DB_CA_CERT_PATH is required and was not set")
+	errClientCertPath = errors.New("DB_CLIENT_CERT_PATH is required and was not set")
+	errClientKeyPath  = errors.New("DB_CLIENT_KEY_PATH is required and was not set")
+	errCertPEM        = errors.New("trusted conn with DB not established, cannot parse cert PEM")
+)
+
+// dbConfFromEnv also returns the tlsOptions it derived from the environment
+// so a caller that wants live cert rotation (see WatchTLSConfig) doesn't
+// have to re-parse DB_CA_CERT_PATH et al itself.
+func dbConfFromEnv() (*mysql.Config, tlsOptions, error) {
+	dconf := &mysql.Config{
+		User:      "root",
+		Passwd:    "",
+		Net:       "tcp",
+		Addr:      "localhost:3306",
+		DBName:    "mycrud",
+		Loc:       time.UTC,
+		ParseTime: true,
+	}
+	if v, ok := os.LookupEnv("DB_USER"); ok {
+		dconf.User = v
+	}
+	if v, ok := os.LookupEnv("DB_PASS"); ok {
+		dconf.Passwd = v
+	}
+	if v, ok := os.LookupEnv("DB_ADDR"); ok {
+		dconf.Addr = v
+	}
+	if v, ok := os.LookupEnv("DB_NAME"); ok {
+		dconf.DBName = v
+	}
+
+	mode := tlsModeVerifyFull
+	if v, ok := os.LookupEnv("DB_TLS_MODE"); ok {
+		mode = v
+	} else if _, ok := os.LookupEnv("DB_SKIP_TLS"); ok {
+		mode = tlsModeDisable
+	}
+
+	clientCertPath := os.Getenv("DB_CLIENT_CERT_PATH")
+	clientKeyPath := os.Getenv("DB_CLIENT_KEY_PATH")
+	opts := tlsOptions{
+		Mode:           mode,
+		CACertPath:     os.Getenv("DB_CA_CERT_PATH"),
+		ClientCertPath: clientCertPath,
+		KeyProvider:    FileKeyProvider{KeyPath: clientKeyPath},
+		ServerName:     os.Getenv("DB_TLS_SERVER_NAME"),
+	}
+	if (mode == tlsModeVerifyCA || mode == tlsModeVerifyFull) && opts.CACertPath == "" {
+		return nil, tlsOptions{}, errCertPath
+	}
+	// verify-ca is the one mode where a client cert is optional (CA-only
+	// trust); every other mode that builds a real tls.Config still requires
+	// one, matching the pre-chunk0-3 behaviour.
+	if mode != tlsModeVerifyCA && mode != tlsModeDisable && mode != tlsModePreferred {
+		if clientCertPath == "" {
+			return nil, tlsOptions{}, errClientCertPath
+		}
+		if clientKeyPath == "" {
+			return nil, tlsOptions{}, errClientKeyPath
+		}
+	}
+	tconf, err := tlsConfig(opts)
+	if err != nil {
+		return nil, tlsOptions{}, err
+	}
+	dconf.TLSConfig = tconf
+	return dconf, opts, nil
+}
+
+// mysqlStore is the UserStore backed by MySQL. It is the original, and still
+// default, backend. mu guards db and dconf so UpdateTLSConfig can swap in a
+// freshly opened *sql.DB while queries are in flight.
+type mysqlStore struct {
+	mu    sync.RWMutex
+	db    *sql.DB
+	dconf *mysql.Config
+}
+
+func newMySQLStore(dconf *mysql.Config) (*mysqlStore, error) {
+	dsn := dconf.FormatDSN()
+	d, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("newMySQLStore Open: %v", scrubPassword(dsn, err))
+	}
+	if err := d.Ping(); err != nil {
+		return nil, fmt.Errorf("newMySQLStore Ping: %v", scrubPassword(dsn, err))
+	}
+	return &mysqlStore{db: d, dconf: dconf}, nil
+}
+
+// UpdateTLSConfig registers newCfg under a fresh mysql.RegisterTLSConfig key
+// and reopens the underlying *sql.DB against a DSN pointing at that key,
+// closing the old connection once the new one is confirmed reachable. It
+// lets a long-running process rotate certificates (see WatchTLSConfig)
+// without restarting, since mysql.RegisterTLSConfig otherwise pins a config
+// for the process lifetime.
+func (s *mysqlStore) UpdateTLSConfig(newCfg *tls.Config) error {
+	key := nextTLSConfigKey()
+	if err := mysql.RegisterTLSConfig(key, newCfg); err != nil {
+		return fmt.Errorf("UpdateTLSConfig: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dconf := *s.dconf
+	dconf.TLSConfig = key
+	dsn := dconf.FormatDSN()
+	newDB, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return fmt.Errorf("UpdateTLSConfig Open: %v", scrubPassword(dsn, err))
+	}
+	if err := newDB.Ping(); err != nil {
+		newDB.Close()
+		return fmt.Errorf("UpdateTLSConfig Ping: %v", scrubPassword(dsn, err))
+	}
+
+	old := s.db
+	s.db = newDB
+	s.dconf = &dconf
+	return old.Close()
+}
+
+func (s *mysqlStore) conn() *sql.DB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db
+}
+
+func (s *mysqlStore) Users() ([]*user, error) {
+	q := `select id,cat,uat,name from user`
+	rows, err := s.conn().Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var uu []*user
+	for rows.Next() {
+		u := user{}
+		if err := rows.Scan(&u.id, &u.createdAt, &u.updatedAt, &u.name); err != nil {
+			return nil, err
+		}
+		uu = append(uu, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return uu, nil
+}
+
+func (s *mysqlStore) UserByID(id string) (*user, error) {
+	q := `select id,cat,uat,name from user where id=?`
+	u := user{}
+	err := s.conn().QueryRow(q, id).Scan(&u.id, &u.createdAt, &u.updatedAt, &u.name)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *mysqlStore) AddUser(name string) error {
+	q := `insert into user(name) values(?)`
+	_, err := s.conn().Exec(q, name)
+	return err
+}
+
+func (s *mysqlStore) DelUser(name string) error {
+	q := `delete from user where name=?`
+	_, err := s.conn().Exec(q, name)
+	return err
+}
+
+func (s *mysqlStore) UpdateUser(oldname, newname string) error {
+	q := `update user set name=? where name=?`
+	_, err := s.conn().Exec(q, newname, oldname)
+	return err
+}
+
+/* user table
+
+create table user (
+	id char(128),
+	cat timestamp default current_timestamp,
+	uat timestamp default current_timestamp on update current_timestamp,
+	name varchar(128)
+	unique (name)
+)
+
+delimiter //
+create trigger init_uuid before insert on user
+  for each row set new.id = uuid();
+//
+delimiter ;
+
+*/
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteConfig holds the settings needed to open the sqlite backend.
+type sqliteConfig struct {
+	// Path is the file path of the sqlite database. Use ":memory:" for a
+	// throwaway, process-local database.
+	Path string
+}
+
+// sqliteConfFromEnv builds a sqliteConfig from DB_SQLITE_PATH, defaulting to
+// a local "mycrud.db" file when unset.
+func sqliteConfFromEnv() sqliteConfig {
+	path := "mycrud.db"
+	if v, ok := os.LookupEnv("DB_SQLITE_PATH"); ok {
+		path = v
+	}
+	return sqliteConfig{Path: path}
+}
+
+// sqliteStore is the UserStore backed by SQLite (via modernc.org/sqlite, a
+// pure Go driver, so no cgo toolchain is required to run the CLI or tests).
+type sqliteStore struct {
+	db *sql.DB
+}
+
+const sqliteSchema = `
+create table if not exists user (
+	id text primary key,
+	cat timestamp not null,
+	uat timestamp not null,
+	name text not null unique
+)`
+
+func newSQLiteStore(conf sqliteConfig) (*sqliteStore, error) {
+	d, err := sql.Open("sqlite", conf.Path)
+	if err != nil {
+		return nil, fmt.Errorf("newSQLiteStore Open: %v", err)
+	}
+	// SQLite serializes writers anyway, and a single connection keeps an
+	// in-memory database (":memory:") from silently becoming a separate,
+	// empty database per pooled connection.
+	d.SetMaxOpenConns(1)
+	if err := d.Ping(); err != nil {
+		return nil, fmt.Errorf("newSQLiteStore Ping: %v", err)
+	}
+	if _, err := d.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("newSQLiteStore schema: %v", err)
+	}
+	return &sqliteStore{db: d}, nil
+}
+
+func (s *sqliteStore) Users() ([]*user, error) {
+	q := `select id,cat,uat,name from user`
+	rows, err := s.db.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var uu []*user
+	for rows.Next() {
+		u := user{}
+		if err := rows.Scan(&u.id, &u.createdAt, &u.updatedAt, &u.name); err != nil {
+			return nil, err
+		}
+		uu = append(uu, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return uu, nil
+}
+
+func (s *sqliteStore) UserByID(id string) (*user, error) {
+	q := `select id,cat,uat,name from user where id=?`
+	u := user{}
+	err := s.db.QueryRow(q, id).Scan(&u.id, &u.createdAt, &u.updatedAt, &u.name)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *sqliteStore) AddUser(name string) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	q := `insert into user(id,cat,uat,name) values(?,?,?,?)`
+	_, err = s.db.Exec(q, id, now, now, name)
+	return err
+}
+
+func (s *sqliteStore) DelUser(name string) error {
+	q := `delete from user where name=?`
+	_, err := s.db.Exec(q, name)
+	return err
+}
+
+func (s *sqliteStore) UpdateUser(oldname, newname string) error {
+	q := `update user set name=?, uat=? where name=?`
+	_, err := s.db.Exec(q, newname, time.Now().UTC(), oldname)
+	return err
+}
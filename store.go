@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// user is a single row of the user table, independent of backend.
+type user struct {
+	id        string
+	createdAt time.Time
+	updatedAt time.Time
+	name      string
+}
+
+func (u *user) String() string {
+	return fmt.Sprintf("[ ID: %s, CreatedAt: %s, UpdatedAt: %s, Name: %q ]",
+		u.id,
+		u.createdAt.UTC().Format(time.RFC3339),
+		u.updatedAt.UTC().Format(time.RFC3339),
+		u.name,
+	)
+}
+
+// UserStore is the set of operations mycrud needs from a backing store. It is
+// implemented by the mysqlStore, sqliteStore, and memoryStore backends so
+// that callers (and tests) can swap the backend without caring which one is
+// in use.
+type UserStore interface {
+	Users() ([]*user, error)
+	UserByID(id string) (*user, error)
+	AddUser(name string) error
+	UpdateUser(oldname, newname string) error
+	DelUser(name string) error
+}
+
+// noopStop is the stop func returned alongside backends that have no
+// background goroutine to shut down.
+func noopStop() error { return nil }
+
+// NewUserStore selects a UserStore implementation based on the DB_DRIVER
+// environment variable ("mysql", "sqlite", or "memory"). It defaults to
+// "mysql" when DB_DRIVER is unset, matching the pre-existing behaviour. The
+// returned stop func shuts down any background goroutine NewUserStore
+// started (currently only the mysql backend's cert-rotation watcher, see
+// WatchTLSConfig); callers should defer it. It is always non-nil, and a
+// no-op when there's nothing to stop.
+func NewUserStore() (UserStore, func() error, error) {
+	driver := "mysql"
+	if v, ok := os.LookupEnv("DB_DRIVER"); ok {
+		driver = v
+	}
+	switch driver {
+	case "mysql":
+		var dconf *mysql.Config
+		var opts tlsOptions
+		var err error
+		if cfgPath, ok := os.LookupEnv("DB_CONFIG_FILE"); ok {
+			dconf, opts, err = LoadConfigFile(cfgPath)
+		} else {
+			dconf, opts, err = dbConfFromEnv()
+		}
+		if err != nil {
+			return nil, noopStop, err
+		}
+		store, err := newMySQLStore(dconf)
+		if err != nil {
+			return nil, noopStop, err
+		}
+		if opts.Mode == tlsModeDisable {
+			return store, noopStop, nil
+		}
+		stop, err := WatchTLSConfig(store, opts)
+		if err != nil {
+			store.db.Close()
+			return nil, noopStop, err
+		}
+		return store, stop, nil
+	case "sqlite":
+		sconf := sqliteConfFromEnv()
+		store, err := newSQLiteStore(sconf)
+		return store, noopStop, err
+	case "memory":
+		return newMemoryStore(), noopStop, nil
+	default:
+		return nil, noopStop, fmt.Errorf("NewUserStore: unknown DB_DRIVER %q", driver)
+	}
+}
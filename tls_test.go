@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCA generates a self-signed CA certificate and writes its PEM
+// encoding to dir/ca.pem, returning the path.
+func writeTestCA(t *testing.T, dir string) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mycrud-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	path := filepath.Join(dir, "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestTLSConfigDisableAndPreferred(t *testing.T) {
+	key, err := tlsConfig(tlsOptions{Mode: tlsModeDisable})
+	if err != nil || key != "false" {
+		t.Fatalf("tlsConfig(disable) = %q, %v, want \"false\", nil", key, err)
+	}
+	key, err = tlsConfig(tlsOptions{Mode: ""})
+	if err != nil || key != "false" {
+		t.Fatalf("tlsConfig(\"\") = %q, %v, want \"false\", nil", key, err)
+	}
+	key, err = tlsConfig(tlsOptions{Mode: tlsModePreferred})
+	if err != nil || key != "preferred" {
+		t.Fatalf("tlsConfig(preferred) = %q, %v, want \"preferred\", nil", key, err)
+	}
+}
+
+func TestTLSConfigUnknownMode(t *testing.T) {
+	if _, err := tlsConfig(tlsOptions{Mode: "bogus"}); err == nil {
+		t.Fatal("tlsConfig(bogus): got nil error, want one")
+	}
+}
+
+func TestBuildTLSConfigSkipVerify(t *testing.T) {
+	for _, mode := range []string{tlsModeRequired, tlsModeSkipVerify} {
+		conf, err := buildTLSConfig(tlsOptions{Mode: mode})
+		if err != nil {
+			t.Fatalf("buildTLSConfig(%s): %v", mode, err)
+		}
+		if !conf.InsecureSkipVerify {
+			t.Fatalf("buildTLSConfig(%s).InsecureSkipVerify = false, want true", mode)
+		}
+	}
+}
+
+func TestBuildTLSConfigVerifyModesRequireCA(t *testing.T) {
+	for _, mode := range []string{tlsModeVerifyCA, tlsModeVerifyFull} {
+		if _, err := buildTLSConfig(tlsOptions{Mode: mode, CACertPath: "/does/not/exist"}); err == nil {
+			t.Fatalf("buildTLSConfig(%s) with a missing CA file: got nil error, want one", mode)
+		}
+	}
+}
+
+func TestBuildTLSConfigVerifyCA(t *testing.T) {
+	caPath := writeTestCA(t, t.TempDir())
+	conf, err := buildTLSConfig(tlsOptions{Mode: tlsModeVerifyCA, CACertPath: caPath})
+	if err != nil {
+		t.Fatalf("buildTLSConfig(verify-ca): %v", err)
+	}
+	if conf.RootCAs == nil {
+		t.Fatal("buildTLSConfig(verify-ca).RootCAs = nil, want the loaded CA pool")
+	}
+	if !conf.InsecureSkipVerify {
+		t.Fatal("buildTLSConfig(verify-ca).InsecureSkipVerify = false, want true (hostname check is skipped, chain check runs in VerifyPeerCertificate)")
+	}
+	if conf.VerifyPeerCertificate == nil {
+		t.Fatal("buildTLSConfig(verify-ca).VerifyPeerCertificate = nil, want the chain-only verifier")
+	}
+}
+
+func TestBuildTLSConfigVerifyFull(t *testing.T) {
+	caPath := writeTestCA(t, t.TempDir())
+	conf, err := buildTLSConfig(tlsOptions{Mode: tlsModeVerifyFull, CACertPath: caPath, ServerName: "db.example.com"})
+	if err != nil {
+		t.Fatalf("buildTLSConfig(verify-full): %v", err)
+	}
+	if conf.RootCAs == nil {
+		t.Fatal("buildTLSConfig(verify-full).RootCAs = nil, want the loaded CA pool")
+	}
+	if conf.InsecureSkipVerify {
+		t.Fatal("buildTLSConfig(verify-full).InsecureSkipVerify = true, want false (full verification)")
+	}
+	if conf.ServerName != "db.example.com" {
+		t.Fatalf("buildTLSConfig(verify-full).ServerName = %q, want db.example.com", conf.ServerName)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchTLSConfig watches the CA and client certificate files referenced by
+// opts and, whenever one changes on disk (e.g. a certificate rotation),
+// rebuilds the tls.Config and hands it to store.UpdateTLSConfig. This
+// mirrors TiDB's SessionManager.UpdateTLSConfig: without it,
+// mysql.RegisterTLSConfig pins a config for the process lifetime and a
+// rotated cert would need a restart to take effect. The returned stop
+// function closes the watcher and stops its goroutine.
+func WatchTLSConfig(store *mysqlStore, opts tlsOptions) (stop func() error, err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("WatchTLSConfig: %v", err)
+	}
+
+	dirs := map[string]bool{}
+	for _, p := range []string{opts.CACertPath, opts.ClientCertPath} {
+		if p == "" {
+			continue
+		}
+		dirs[filepath.Dir(p)] = true
+	}
+	if opts.KeyProvider != nil {
+		for _, dir := range opts.KeyProvider.WatchDirs() {
+			dirs[dir] = true
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("WatchTLSConfig: %v", err)
+		}
+	}
+
+	go func() {
+		for event := range watcher.Events {
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			conf, err := buildTLSConfig(opts)
+			if err != nil {
+				log.Printf("WatchTLSConfig: rebuild tls config: %v", err)
+				continue
+			}
+			if err := store.UpdateTLSConfig(conf); err != nil {
+				log.Printf("WatchTLSConfig: update tls config: %v", err)
+			}
+		}
+	}()
+
+	return watcher.Close, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// TestWatchTLSConfigReloadsOnCertChange confirms the fsnotify plumbing: when
+// the watched CA file changes on disk, WatchTLSConfig rebuilds the
+// tls.Config and calls store.UpdateTLSConfig. There's no live MySQL server
+// in this sandbox for UpdateTLSConfig's Ping to succeed against, so this
+// test points the store at a closed local port and asserts the reload was
+// attempted (and failed, as expected) by capturing WatchTLSConfig's log
+// output rather than asserting a live reconnect.
+func TestWatchTLSConfigReloadsOnCertChange(t *testing.T) {
+	dir := t.TempDir()
+	caPath := writeTestCA(t, dir)
+
+	db, err := sql.Open("mysql", "root:x@tcp(127.0.0.1:1)/mycrud")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	store := &mysqlStore{db: db, dconf: &mysql.Config{Net: "tcp", Addr: "127.0.0.1:1", DBName: "mycrud"}}
+
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	stop, err := WatchTLSConfig(store, tlsOptions{Mode: tlsModeVerifyCA, CACertPath: caPath})
+	if err != nil {
+		t.Fatalf("WatchTLSConfig: %v", err)
+	}
+	defer stop()
+
+	// Rewrite the CA file in place so fsnotify sees a Write event.
+	caPEM, err := os.ReadFile(caPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ca.pem"), caPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if strings.Contains(logBuf.String(), "WatchTLSConfig: update tls config") {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("WatchTLSConfig: no reload was attempted after the CA file changed; log = %q", logBuf.String())
+}